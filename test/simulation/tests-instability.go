@@ -1,13 +1,195 @@
 package main
 
 import (
-	"fmt"
+	"sync"
 	"time"
 
+	"github.com/ExchangeUnion/xud-simulation/wait"
 	"github.com/ExchangeUnion/xud-simulation/xudrpc"
 	"github.com/ExchangeUnion/xud-simulation/xudtest"
 )
 
+// swapRecoveryTimeout bounds how long the waitForSwap* helpers wait for a
+// swap event (recovered, paid, accepted or failed), replacing the fixed
+// sleeps previously used here.
+const swapRecoveryTimeout = 15 * time.Second
+
+// swapEventTracker consumes a SubscribeSwaps stream in the background and
+// records every event it sees keyed by rHash, so that wait.Predicate can
+// poll for a given swap's state instead of the test sleeping for a fixed
+// duration. Keying by rHash keeps swaps distinct when more than one could
+// be in flight on the same node.
+type swapEventTracker struct {
+	mu     sync.Mutex
+	states map[string]map[xudrpc.SwapState]bool
+}
+
+// trackSwaps opens a SubscribeSwaps stream on node and starts recording the
+// states it emits. It must be called before the event being waited on can
+// possibly fire (e.g. before a crash/restart), since SubscribeSwaps does not
+// replay past events.
+func trackSwaps(ht *harnessTest, node *xudtest.HarnessXud) (*swapEventTracker, error) {
+	stream, err := node.Client.SubscribeSwaps(ht.ctx, &xudrpc.SubscribeSwapsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := &swapEventTracker{states: make(map[string]map[xudrpc.SwapState]bool)}
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			tracker.mu.Lock()
+			if tracker.states[event.RHash] == nil {
+				tracker.states[event.RHash] = make(map[xudrpc.SwapState]bool)
+			}
+			tracker.states[event.RHash][event.State] = true
+			tracker.mu.Unlock()
+		}
+	}()
+
+	return tracker, nil
+}
+
+// hasSeen reports whether the tracker has recorded the given swap state for
+// the swap identified by rHash. An empty rHash matches the state against any
+// swap the tracker has seen, which is fine for tests with only one swap in
+// flight and lets existing call sites that don't have an rHash handy keep
+// working unchanged.
+func (t *swapEventTracker) hasSeen(rHash string, state xudrpc.SwapState) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rHash != "" {
+		return t.states[rHash][state]
+	}
+	for _, states := range t.states {
+		if states[state] {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForSwapRecovered blocks until the tracker observes a SWAP_RECOVERED
+// event for rHash (or for any swap, if rHash is empty), or
+// swapRecoveryTimeout elapses.
+func waitForSwapRecovered(t *swapEventTracker, rHash string) error {
+	return wait.Predicate(func() bool {
+		return t.hasSeen(rHash, xudrpc.SwapState_SWAP_RECOVERED)
+	}, swapRecoveryTimeout)
+}
+
+// waitForSwapPaid blocks until the tracker observes a SWAP_PAID event for
+// rHash (or for any swap, if rHash is empty), or swapRecoveryTimeout
+// elapses. Unlike waitForSwapRecovered, this is for the happy path where the
+// swap settles normally rather than being recovered after a crash.
+func waitForSwapPaid(t *swapEventTracker, rHash string) error {
+	return wait.Predicate(func() bool {
+		return t.hasSeen(rHash, xudrpc.SwapState_SWAP_PAID)
+	}, swapRecoveryTimeout)
+}
+
+// waitForSwapAccepted blocks until the tracker observes a SWAP_ACCEPTED
+// event for rHash (or for any swap, if rHash is empty), or
+// swapRecoveryTimeout elapses.
+func waitForSwapAccepted(t *swapEventTracker, rHash string) error {
+	return wait.Predicate(func() bool {
+		return t.hasSeen(rHash, xudrpc.SwapState_SWAP_ACCEPTED)
+	}, swapRecoveryTimeout)
+}
+
+// waitForSwapFailed blocks until the tracker observes a SWAP_FAILED event
+// for rHash (or for any swap, if rHash is empty), or swapRecoveryTimeout
+// elapses.
+func waitForSwapFailed(t *swapEventTracker, rHash string) error {
+	return wait.Predicate(func() bool {
+		return t.hasSeen(rHash, xudrpc.SwapState_SWAP_FAILED)
+	}, swapRecoveryTimeout)
+}
+
+// The fault constructors below build a xudrpc.FaultSpec for use with
+// ht.act.injectFault. They replace the old CUSTOM_SCENARIO env strings
+// (and side-channel CLIENT_PID/CLIENT_TYPE vars) with a declarative,
+// composable description of the instability to inject into a running node,
+// so new scenarios don't require a new CUSTOM_SCENARIO constant.
+
+// CrashAfter builds a FaultSpec that crashes xud immediately after it
+// reaches the given stage of the swap protocol. xud does not come back on
+// its own; callers must wait on the node's ProcessExit and restart it.
+func CrashAfter(stage xudrpc.FaultStage) *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_CrashAfter{
+			CrashAfter: &xudrpc.CrashAfterFault{Stage: stage},
+		},
+	}
+}
+
+// ForceCloseAfter builds a FaultSpec that force-closes the channel with
+// xud's swap counterparty immediately after xud reaches the given stage of
+// the swap protocol. Unlike CrashAfter, xud itself keeps running
+// afterward - only the channel goes on-chain.
+func ForceCloseAfter(stage xudrpc.FaultStage) *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_ForceCloseAfter{
+			ForceCloseAfter: &xudrpc.ForceCloseAfterFault{Stage: stage},
+		},
+	}
+}
+
+// DelayBefore builds a FaultSpec that delays xud for the given duration
+// before it reaches the given stage of the swap protocol.
+func DelayBefore(stage xudrpc.FaultStage, delay time.Duration) *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_DelayBefore{
+			DelayBefore: &xudrpc.DelayBeforeFault{Stage: stage, Duration: delay.String()},
+		},
+	}
+}
+
+// KillClient builds a FaultSpec that kills the given swap client process
+// (e.g. lnd-ltc, the connext client) out from under a running xud, in
+// place of restarting xud with a CLIENT_PID/CLIENT_TYPE env pair.
+func KillClient(clientType xudrpc.ClientType) *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_KillClient{
+			KillClient: &xudrpc.KillClientFault{ClientType: clientType},
+		},
+	}
+}
+
+// DropMessage builds a FaultSpec that drops the next n p2p messages of the
+// given type.
+func DropMessage(messageType xudrpc.P2pMessageType, n uint32) *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_DropMessage{
+			DropMessage: &xudrpc.DropMessageFault{MessageType: messageType, Count: n},
+		},
+	}
+}
+
+// CorruptPreimage builds a FaultSpec that substitutes a random preimage for
+// the real one just before a settle message is sent.
+func CorruptPreimage() *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_CorruptPreimage{
+			CorruptPreimage: &xudrpc.CorruptPreimageFault{},
+		},
+	}
+}
+
+// DisconnectPeer builds a FaultSpec that tears down the p2p connection to
+// the swap counterparty mid-swap, without touching either node's clients or
+// process.
+func DisconnectPeer() *xudrpc.FaultSpec {
+	return &xudrpc.FaultSpec{
+		Fault: &xudrpc.FaultSpec_DisconnectPeer{
+			DisconnectPeer: &xudrpc.DisconnectPeerFault{},
+		},
+	}
+}
+
 var ltcQuantity int64 = 1000000
 
 // instabilityTestCases are test cases which try to simulate instability
@@ -42,15 +224,161 @@ var instabilityTestCases = []*testCase{
 	//	name: "maker crashed after send payment with delayed settlement; incoming: lnd, outgoing: connext", // replacing Alice + Bob
 	//	test: testMakerCrashedAfterSendDelayedSettlementConnextOut,
 	//},
+	{
+		name: "maker force-closes before settling, taker extracts preimage on-chain", // replacing Alice
+		test: testMakerSettleOnChain,
+	},
+	{
+		name: "taker's outgoing client becomes unavailable mid-swap", // replacing Bob
+		test: testSwapFailureClientUnavailable,
+	},
+	{
+		name: "taker sends a payment with an incorrect preimage", // replacing Bob
+		test: testSwapFailureIncorrectPreimage,
+	},
+	{
+		name: "taker delays settlement past the maker's payment timeout", // replacing Bob
+		test: testSwapFailurePaymentTimeout,
+	},
+	{
+		name: "taker drops the swap-failed message like a legacy peer", // replacing Bob
+		test: testSwapFailureLegacyNoDetail,
+	},
+	{
+		name: "maker has no route to pay out her side of the swap", // no fault injected
+		test: testSwapFailureNoRoute,
+	},
+	{
+		name: "maker's order exhausts the taker's inbound capacity", // no fault injected
+		test: testSwapFailureInsufficientInboundCapacity,
+	},
+	{
+		name: "taker disconnects right after the order match", // replacing Bob
+		test: testSwapFailurePeerDisconnected,
+	},
+	{
+		name: "maker delays her own settle step past the settlement timeout", // replacing Alice
+		test: testSwapFailureSettlementTimeout,
+	},
+	{
+		name: "maker holds incoming HTLC across a restart, then settles", // replacing Alice
+		test: testHoldSwapRestartThenSettle,
+	},
+	{
+		name: "maker holds incoming HTLC and cancels", // no fault injected
+		test: testHoldSwapCancel,
+	},
+	{
+		name: "maker holds incoming HTLC across a connext client crash, then settles", // replacing Alice
+		test: testHoldSwapConnextCrashThenSettle,
+	},
+	{
+		name: "three-hop swap routed through Carol", // adds Carol
+		test: testThreeHopSwap,
+	},
+	{
+		name: "three-hop swap; Carol crashes mid-route", // adds Carol
+		test: testThreeHopCarolCrashMidRoute,
+	},
+	{
+		name: "three-hop swap; Carol's lnd-ltc dies before forwarding the settle", // adds Carol
+		test: testThreeHopCarolLndLtcDiesBeforeForward,
+	},
+	{
+		name: "three-hop swap; Carol's connext client restarts with an ETH leg inflight", // adds Carol
+		test: testThreeHopCarolConnextRestartMidRoute,
+	},
+}
+
+// htlcExpiryDelta is the number of blocks mined past the HTLC's CLTV expiry
+// to force the outgoing leg into its on-chain claim path.
+const htlcExpiryDelta = 40
+
+// testMakerSettleOnChain reproduces the case where the maker (Alice) has
+// already learned the preimage for her incoming HTLC but is force-closed
+// before she can relay a settle message back to the taker (Bob). Bob must
+// recover by watching the chain, extracting the preimage from Alice's
+// sweep transaction, and settling his own outgoing HTLC with it - mirroring
+// lnd's testMultiHopReceiverChainClaim.
+func testMakerSettleOnChain(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+
+	// Alice's channel with Bob is force-closed right after she learns the
+	// preimage for her incoming HTLC, before she can relay a settle message
+	// back to Bob over the wire. Her xud process itself keeps running
+	// throughout - it's the channel, not xud, that goes on-chain here.
+	err := ht.act.injectFault(net.Alice, ForceCloseAfter(xudrpc.FaultStage_STAGE_RECEIVE_PREIMAGE))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Save the initial balance.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// The injected fault force-closes Alice's LTC channel with Bob right
+	// after she learns the preimage, before she can send a settle message,
+	// simulating the off-chain path being unavailable.
+	err = net.Alice.LtcMiner.MineBlocks(1)
+	ht.assert.NoError(err)
+
+	// Mine past the HTLC's CLTV expiry so that Alice is forced onto her
+	// sweep (success) path rather than waiting for a cooperative settle.
+	err = net.Alice.LtcMiner.MineBlocks(htlcExpiryDelta)
+	ht.assert.NoError(err)
+
+	// Alice should sweep her incoming HTLC on-chain using the preimage.
+	aliceSweepTx, err := net.Alice.LtcMiner.GetTxInMempool()
+	ht.assert.NoError(err)
+	ht.assert.NotNil(aliceSweepTx, "alice did not broadcast her HTLC sweep transaction")
+
+	// Mine the sweep so Bob's lnd-ltc can observe it and extract the preimage.
+	err = net.Alice.LtcMiner.MineBlocks(1)
+	ht.assert.NoError(err)
+
+	// Bob should extract the preimage from Alice's sweep and mark his
+	// outgoing HTLC settled on-chain rather than off-chain.
+	bobInfo, err := net.Bob.Client.GetInfo(ht.ctx, &xudrpc.GetInfoRequest{})
+	ht.assert.NoError(err)
+	ht.assert.Contains(bobInfo.PendingSwapResolutions, "ONCHAIN", "bob did not resolve the swap on-chain")
+
+	// Verify that alice received her LTC via the sweep.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	aliceLtcBalance := aliceBalance.ltc.channel.GetBalance()
+	ht.assert.Equal(alicePrevLtcBalance+ltcQuantity, aliceLtcBalance, "alice did not sweep her LTC on-chain")
 }
 
 // testMakerLndCrashedBeforeSettlement
 func testMakerCrashedAfterSend(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{"CUSTOM_SCENARIO=INSTABILITY::MAKER_CRASH_AFTER_SEND"})
-	ht.assert.NoError(err)
 	ht.act.init(net.Alice)
 
+	err := ht.act.injectFault(net.Alice, CrashAfter(xudrpc.FaultStage_STAGE_SEND_PAYMENT))
+	ht.assert.NoError(err)
+
 	// Connect Alice to Bob.
 	ht.act.connect(net.Alice, net.Bob)
 	ht.act.verifyConnectivity(net.Alice, net.Bob)
@@ -87,8 +415,12 @@ func testMakerCrashedAfterSend(net *xudtest.NetworkHarness, ht *harnessTest) {
 	err = net.Alice.Start(nil)
 	ht.assert.NoError(err)
 
-	// Brief delay to allow for swap to be recovered consistently
-	time.Sleep(1 * time.Second)
+	// Subscribe to Alice's swap events now that her xud is back up, and
+	// wait for the recovered swap to be reported rather than sleeping.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that alice received her LTC
 	aliceBalance, err := getBalance(ht.ctx, net.Alice)
@@ -98,15 +430,11 @@ func testMakerCrashedAfterSend(net *xudtest.NetworkHarness, ht *harnessTest) {
 }
 
 func testMakerLndCrashedBeforeSettlement(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{
-		"CUSTOM_SCENARIO=INSTABILITY::MAKER_CLIENT_CRASHED_BEFORE_SETTLE",
-		fmt.Sprintf("CLIENT_PID=%d", net.Alice.LndLtcNode.Cmd.Process.Pid),
-		fmt.Sprintf("CLIENT_TYPE=%s", "LndLtc"),
-	})
-	ht.assert.NoError(err)
 	ht.act.init(net.Alice)
 
+	err := ht.act.injectFault(net.Alice, KillClient(xudrpc.ClientType_LND_LTC))
+	ht.assert.NoError(err)
+
 	// Connect Alice to Bob.
 	ht.act.connect(net.Alice, net.Bob)
 	ht.act.verifyConnectivity(net.Alice, net.Bob)
@@ -124,6 +452,11 @@ func testMakerLndCrashedBeforeSettlement(net *xudtest.NetworkHarness, ht *harnes
 		PairId:   "LTC/BTC",
 		Side:     xudrpc.OrderSide_BUY,
 	}
+	// Subscribe to Alice's swap events before the crash; her xud process
+	// itself stays up throughout this test, only her lnd-ltc dies.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
 	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
 
 	// Place a matching order on Bob.
@@ -146,10 +479,12 @@ func testMakerLndCrashedBeforeSettlement(net *xudtest.NetworkHarness, ht *harnes
 	err = net.Alice.LndLtcNode.Start(nil)
 	ht.assert.NoError(err)
 
-	// Brief delay to allow for swap to be recovered consistently.
+	// Wait for the swap to be reported recovered instead of sleeping for a
+	// fixed duration.
 	// The pending swap recheck interval is usually 5m, but was adjusted in
 	// Alice's custom xud to 5s (as well as the swap completion timeout interval).
-	time.Sleep(10 * time.Second)
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that alice received her LTC.
 	aliceBalance, err := getBalance(ht.ctx, net.Alice)
@@ -159,15 +494,11 @@ func testMakerLndCrashedBeforeSettlement(net *xudtest.NetworkHarness, ht *harnes
 }
 
 func testMakerConnextClientCrashedBeforeSettlement(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{
-		"CUSTOM_SCENARIO=INSTABILITY::MAKER_CLIENT_CRASHED_BEFORE_SETTLE",
-		fmt.Sprintf("CLIENT_PID=%d", net.Alice.ConnextClient.Cmd.Process.Pid),
-		fmt.Sprintf("CLIENT_TYPE=%s", "ConnextClient"),
-	})
-	ht.assert.NoError(err)
 	ht.act.init(net.Alice)
 
+	err := ht.act.injectFault(net.Alice, KillClient(xudrpc.ClientType_CONNEXT_CLIENT))
+	ht.assert.NoError(err)
+
 	ht.act.initConnext(net, net.Alice, false)
 	ht.act.initConnext(net, net.Bob, true)
 
@@ -195,6 +526,11 @@ func testMakerConnextClientCrashedBeforeSettlement(net *xudtest.NetworkHarness,
 		PairId:   "BTC/ETH",
 		Side:     xudrpc.OrderSide_SELL,
 	}
+	// Subscribe to Alice's swap events before the crash; her xud process
+	// itself stays up throughout this test, only her connext client dies.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
 	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
 
 	// Place a matching order on Bob.
@@ -220,16 +556,12 @@ func testMakerConnextClientCrashedBeforeSettlement(net *xudtest.NetworkHarness,
 	err = waitConnextReady(net.Alice)
 	ht.assert.NoError(err)
 
-	// Brief delay to allow for swap to be recovered consistently.
+	// Wait for the swap to be reported recovered instead of sleeping for a
+	// fixed duration.
 	// The pending swap recheck interval is usually 5m, but was adjusted in
 	// Alice's custom xud to 5s (as well as the swap completion timeout interval).
-	time.Sleep(10 * time.Second)
-
-	// <DEBUG>
-	info, err := net.Alice.Client.GetInfo(ht.ctx, &xudrpc.GetInfoRequest{})
-	fmt.Printf("info: %v\n", info.PendingSwapHashes)
-	//time.Sleep(600 * time.Second)
-	// </DEBUG>
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that alice received her ETH.
 	aliceBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "ETH"})
@@ -240,15 +572,14 @@ func testMakerConnextClientCrashedBeforeSettlement(net *xudtest.NetworkHarness,
 }
 
 func testMakerCrashedAfterSendDelayedSettlement(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{"CUSTOM_SCENARIO=INSTABILITY::MAKER_CRASH_AFTER_SEND"})
-	ht.assert.NoError(err)
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
 
-	net.Bob, err = net.SetCustomXud(ht.ctx, ht, net.Bob, []string{"CUSTOM_SCENARIO=INSTABILITY::TAKER_DELAY_BEFORE_SETTLE"})
+	err := ht.act.injectFault(net.Alice, CrashAfter(xudrpc.FaultStage_STAGE_SEND_PAYMENT))
 	ht.assert.NoError(err)
 
-	ht.act.init(net.Alice)
-	ht.act.init(net.Bob)
+	err = ht.act.injectFault(net.Bob, DelayBefore(xudrpc.FaultStage_STAGE_FORWARD_SETTLE, 8*time.Second))
+	ht.assert.NoError(err)
 
 	// Connect Alice to Bob.
 	ht.act.connect(net.Alice, net.Bob)
@@ -283,6 +614,11 @@ func testMakerCrashedAfterSendDelayedSettlement(net *xudtest.NetworkHarness, ht
 
 	net.Alice.Start(nil)
 
+	// Subscribe to Alice's swap events now that her xud is back up, and
+	// wait for the recovered swap to be reported rather than sleeping.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
 	// Verify that alice hasn't claimed her LTC yet. The incoming LTC payment
 	// cannot be settled until the outgoing BTC payment is settled by bob,
 	// which is being intentionally delayed.
@@ -291,8 +627,9 @@ func testMakerCrashedAfterSendDelayedSettlement(net *xudtest.NetworkHarness, ht
 	aliceIntermediateLtcBalance := aliceIntermediateBalance.ltc.channel.GetBalance()
 	ht.assert.Less(aliceIntermediateLtcBalance, alicePrevLtcBalance)
 
-	// Delay to allow for payment to be claimed by bob then recovered by alice
-	time.Sleep(10 * time.Second)
+	// Wait for the payment to be claimed by bob then recovered by alice.
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that alice received her LTC
 	aliceBalance, err := getBalance(ht.ctx, net.Alice)
@@ -302,19 +639,18 @@ func testMakerCrashedAfterSendDelayedSettlement(net *xudtest.NetworkHarness, ht
 }
 
 func testMakerCrashedAfterSendDelayedSettlementConnextOut(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{"CUSTOM_SCENARIO=INSTABILITY::MAKER_CRASH_AFTER_SEND"})
-	ht.assert.NoError(err)
-
-	net.Bob, err = net.SetCustomXud(ht.ctx, ht, net.Bob, []string{"CUSTOM_SCENARIO=INSTABILITY::TAKER_DELAY_BEFORE_SETTLE"})
-	ht.assert.NoError(err)
-
 	ht.act.init(net.Alice)
 	ht.act.initConnext(net, net.Alice, true)
 
 	ht.act.init(net.Bob)
 	ht.act.initConnext(net, net.Bob, false)
 
+	err := ht.act.injectFault(net.Alice, CrashAfter(xudrpc.FaultStage_STAGE_SEND_PAYMENT))
+	ht.assert.NoError(err)
+
+	err = ht.act.injectFault(net.Bob, DelayBefore(xudrpc.FaultStage_STAGE_FORWARD_SETTLE, 8*time.Second))
+	ht.assert.NoError(err)
+
 	// Connect Alice to Bob.
 	ht.act.connect(net.Alice, net.Bob)
 	ht.act.verifyConnectivity(net.Alice, net.Bob)
@@ -359,6 +695,11 @@ func testMakerCrashedAfterSendDelayedSettlementConnextOut(net *xudtest.NetworkHa
 	err = waitConnextReady(net.Alice)
 	ht.assert.NoError(err)
 
+	// Subscribe to Alice's swap events now that her xud is back up, and
+	// wait for the recovered swap to be reported rather than sleeping.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
 	// Verify that alice hasn't claimed her BTC yet. The incoming BTC payment
 	// cannot be settled until the outgoing ETH payment is settled by bob,
 	// which is being intentionally delayed.
@@ -372,8 +713,9 @@ func testMakerCrashedAfterSendDelayedSettlementConnextOut(net *xudtest.NetworkHa
 	bobIntermediateEthBalance := bobIntermediateBalance.Balances["ETH"]
 	ht.assert.Equal(bobPrevEthBalance.ChannelBalance, bobIntermediateEthBalance.ChannelBalance)
 
-	// Wait to allow the ETH payment to be claimed by bob and then recovered by alice.
-	time.Sleep(10 * time.Second)
+	// Wait for the ETH payment to be claimed by bob and then recovered by alice.
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that both parties received their payment.
 	aliceBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "BTC"})
@@ -390,19 +732,18 @@ func testMakerCrashedAfterSendDelayedSettlementConnextOut(net *xudtest.NetworkHa
 }
 
 func testMakerCrashedAfterSendDelayedSettlementConnextIn(net *xudtest.NetworkHarness, ht *harnessTest) {
-	var err error
-	net.Alice, err = net.SetCustomXud(ht.ctx, ht, net.Alice, []string{"CUSTOM_SCENARIO=INSTABILITY::MAKER_CRASH_AFTER_SEND"})
-	ht.assert.NoError(err)
-
-	net.Bob, err = net.SetCustomXud(ht.ctx, ht, net.Bob, []string{"CUSTOM_SCENARIO=INSTABILITY::TAKER_DELAY_BEFORE_SETTLE"})
-	ht.assert.NoError(err)
-
 	ht.act.init(net.Alice)
 	ht.act.initConnext(net, net.Alice, false)
 
 	ht.act.init(net.Bob)
 	ht.act.initConnext(net, net.Bob, true)
 
+	err := ht.act.injectFault(net.Alice, CrashAfter(xudrpc.FaultStage_STAGE_SEND_PAYMENT))
+	ht.assert.NoError(err)
+
+	err = ht.act.injectFault(net.Bob, DelayBefore(xudrpc.FaultStage_STAGE_FORWARD_SETTLE, 8*time.Second))
+	ht.assert.NoError(err)
+
 	// Connect Alice to Bob.
 	ht.act.connect(net.Alice, net.Bob)
 	ht.act.verifyConnectivity(net.Alice, net.Bob)
@@ -447,6 +788,11 @@ func testMakerCrashedAfterSendDelayedSettlementConnextIn(net *xudtest.NetworkHar
 	err = waitConnextReady(net.Alice)
 	ht.assert.NoError(err)
 
+	// Subscribe to Alice's swap events now that her xud is back up, and
+	// wait for the recovered swap to be reported rather than sleeping.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
 	// Verify that alice hasn't claimed her ETH yet. The incoming ETH payment
 	// cannot be settled until the outgoing BTC payment is settled by bob,
 	// which is being intentionally delayed.
@@ -460,8 +806,9 @@ func testMakerCrashedAfterSendDelayedSettlementConnextIn(net *xudtest.NetworkHar
 	bobIntermediateBtcBalance := bobIntermediateBalance.Balances["BTC"]
 	ht.assert.Equal(bobPrevBtcBalance.ChannelBalance, bobIntermediateBtcBalance.ChannelBalance)
 
-	// Wait to allow the ETH payment to be claimed by bob and then recovered by alice.
-	time.Sleep(10 * time.Second)
+	// Wait for the ETH payment to be claimed by bob and then recovered by alice.
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
 
 	// Verify that both parties received their payment.
 	aliceBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "ETH"})
@@ -476,3 +823,946 @@ func testMakerCrashedAfterSendDelayedSettlementConnextIn(net *xudtest.NetworkHar
 	diff = bobOrderReq.Quantity
 	ht.assert.Equal(bobPrevBtcBalance.ChannelBalance+diff, bobBtcBalance.ChannelBalance)
 }
+
+// subscribeSwapFailure opens a SubscribeSwapFailures stream and returns at
+// once with two channels: the first failure reported is delivered on the
+// first, and an error from the stream (e.g. the context being done) on the
+// second. The caller blocks on them.
+func subscribeSwapFailure(ht *harnessTest, node *xudtest.HarnessXud) (<-chan *xudrpc.SwapFailure, <-chan error, error) {
+	stream, err := node.Client.SubscribeSwapFailures(ht.ctx, &xudrpc.SubscribeSwapFailuresRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	failureCh := make(chan *xudrpc.SwapFailure, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		failure, err := stream.Recv()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		failureCh <- failure
+	}()
+
+	return failureCh, errCh, nil
+}
+
+// testSwapFailureClientUnavailable kills the taker's outgoing swap client
+// (lnd-ltc) mid-swap and asserts that the resulting swap failure is reported
+// with FailureCode_CLIENT_UNAVAILABLE and a FailureDetail describing which
+// client went away, rather than a bare "swap failed" with no detail.
+func testSwapFailureClientUnavailable(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Bob, KillClient(xudrpc.ClientType_LND_LTC))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; Bob's custom xud kills his own lnd-ltc
+	// before forwarding the outgoing payment.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_CLIENT_UNAVAILABLE, failure.FailureCode)
+		ht.assert.NotEqual(xudrpc.FailureDetail_NO_DETAIL, failure.FailureDetail)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailureIncorrectPreimage has the taker respond with a
+// deliberately wrong preimage and asserts that the maker's swap failure is
+// reported as FailureCode_INCORRECT_PREIMAGE, matching the
+// linkErr.FailureDetail == nil guard pattern only for legacy paths that
+// don't yet populate a detail.
+func testSwapFailureIncorrectPreimage(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Bob, CorruptPreimage())
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; Bob's custom xud sends back a corrupted
+	// preimage instead of settling correctly.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_INCORRECT_PREIMAGE, failure.FailureCode)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailurePaymentTimeout delays the taker's settlement well past the
+// maker's payment timeout and asserts that the resulting swap failure is
+// reported as FailureCode_PAYMENT_TIMEOUT instead of eventually recovering.
+func testSwapFailurePaymentTimeout(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Bob, DelayBefore(xudrpc.FaultStage_STAGE_FORWARD_SETTLE, 2*time.Minute))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; Bob's custom xud delays forwarding the
+	// settle message well past Alice's payment timeout.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_PAYMENT_TIMEOUT, failure.FailureCode)
+	case <-time.After(3 * time.Minute):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailureLegacyNoDetail drops the taker's swap-failed message
+// entirely, simulating an older peer that reports a swap failure without
+// populating a FailureDetail, and asserts that the legacy
+// FailureDetail_NO_DETAIL fallback is still surfaced rather than xud
+// crashing on a nil detail.
+func testSwapFailureLegacyNoDetail(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Bob, DropMessage(xudrpc.P2pMessageType_SWAP_FAILED, 1))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; Bob's custom xud drops the swap-failed
+	// message it would otherwise send, as a legacy peer might.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.FailureDetail_NO_DETAIL, failure.FailureDetail)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailureNoRoute has the maker place an order for a currency pair
+// she has no channel open for, so her swap client can't find a path to pay
+// out her side of the swap, and asserts that the resulting failure is
+// reported as FailureCode_NO_ROUTE.
+func testSwapFailureNoRoute(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.initConnext(net, net.Alice, false)
+	ht.act.init(net.Bob)
+	ht.act.initConnext(net, net.Bob, true)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Deliberately skip opening an ETH channel for Alice, so she has no
+	// path to pay out her side of a BTC/ETH swap.
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice that requires paying out ETH she has no
+	// channel for.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    40,
+		Quantity: 1,
+		PairId:   "BTC/ETH",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_NO_ROUTE, failure.FailureCode)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailureInsufficientInboundCapacity has the maker place an order
+// far larger than the taker's inbound channel capacity, exhausting his
+// available inbound liquidity for the swap, and asserts that the resulting
+// failure is reported as FailureCode_INSUFFICIENT_INBOUND_CAPACITY.
+func testSwapFailureInsufficientInboundCapacity(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order far larger than Bob's available inbound capacity for
+	// BTC, so his incoming BTC HTLC can't be accepted on his side.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity) * 1000,
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_INSUFFICIENT_INBOUND_CAPACITY, failure.FailureCode)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailurePeerDisconnected tears down the p2p connection between
+// maker and taker right after the order match, before either side can begin
+// the swap, and asserts that the resulting failure is reported as
+// FailureCode_PEER_DISCONNECTED.
+func testSwapFailurePeerDisconnected(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Bob, DisconnectPeer())
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; Bob's custom xud disconnects from
+	// Alice as soon as the match is made, before the swap can begin.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_PEER_DISCONNECTED, failure.FailureCode)
+	case <-time.After(30 * time.Second):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testSwapFailureSettlementTimeout delays the maker's own settle step past
+// the swap's settlement timeout after she already holds the preimage, and
+// asserts that the resulting failure is reported as
+// FailureCode_SETTLEMENT_TIMEOUT rather than a payment-side timeout.
+func testSwapFailureSettlementTimeout(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	err := ht.act.injectFault(net.Alice, DelayBefore(xudrpc.FaultStage_STAGE_SEND_SETTLE, 2*time.Minute))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Subscribe before placing orders so the failure event can't be missed.
+	failureCh, errCh, err := subscribeSwapFailure(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	select {
+	case err := <-errCh:
+		ht.assert.NoError(err)
+	case failure := <-failureCh:
+		ht.assert.Equal(xudrpc.Failure_SETTLEMENT_TIMEOUT, failure.FailureCode)
+	case <-time.After(3 * time.Minute):
+		ht.assert.Fail("timed out waiting for swap failure")
+	}
+}
+
+// testHoldSwapRestartThenSettle has Alice place a hold order so that her
+// incoming HTLC is accepted but not settled, restarts her xud while the
+// swap is held, and then explicitly settles it with SettleSwap. It asserts
+// that the held swap survives the restart and that funds only move once
+// SettleSwap is called, rather than as soon as the HTLC is accepted.
+func testHoldSwapRestartThenSettle(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Save the initial balance.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	// Place a hold order on Alice; her incoming HTLC will be accepted but
+	// held rather than settled immediately.
+	aliceOrderReq := &xudrpc.PlaceHoldOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	// Subscribe before placing the matching order so the accepted event
+	// can't be missed.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	holdAck, err := net.Alice.Client.PlaceHoldOrder(ht.ctx, aliceOrderReq)
+	ht.assert.NoError(err)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Wait for the HTLC to be accepted before restarting.
+	err = waitForSwapAccepted(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	// Restart Alice's xud. The held swap's preimage is not yet released,
+	// so it must be recovered from the swap DB rather than in-memory state.
+	err = net.Alice.Stop()
+	ht.assert.NoError(err)
+	err = net.Alice.Start(nil)
+	ht.assert.NoError(err)
+
+	// Alice still hasn't released the preimage, so she hasn't received her LTC.
+	aliceHeldBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	ht.assert.Equal(alicePrevLtcBalance, aliceHeldBalance.ltc.channel.GetBalance())
+
+	// Subscribe again; the previous stream broke when Alice's xud restarted.
+	tracker, err = trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Now explicitly settle the held swap.
+	_, err = net.Alice.Client.SettleSwap(ht.ctx, &xudrpc.SettleSwapRequest{RHash: holdAck.RHash})
+	ht.assert.NoError(err)
+
+	// Wait for the settle to propagate rather than sleeping.
+	err = waitForSwapPaid(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	// Verify that alice received her LTC only after the explicit settle.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	aliceLtcBalance := aliceBalance.ltc.channel.GetBalance()
+	ht.assert.Equal(alicePrevLtcBalance+ltcQuantity, aliceLtcBalance, "alice did not receive LTC after settling the held swap")
+}
+
+// testHoldSwapCancel has Alice hold her incoming HTLC and then cancel it
+// via CancelSwap, asserting that the taker's outgoing HTLC is cleanly
+// returned rather than left dangling.
+func testHoldSwapCancel(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	// Save the initial balances.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	bobPrevBalance, err := getBalance(ht.ctx, net.Bob)
+	ht.assert.NoError(err)
+	bobPrevBtcBalance := bobPrevBalance.btc.channel.GetBalance()
+
+	// Place a hold order on Alice.
+	aliceOrderReq := &xudrpc.PlaceHoldOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	// Subscribe before placing the matching order so the accepted and
+	// failed events can't be missed.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	holdAck, err := net.Alice.Client.PlaceHoldOrder(ht.ctx, aliceOrderReq)
+	ht.assert.NoError(err)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Wait for the HTLC to be accepted before cancelling.
+	err = waitForSwapAccepted(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	_, err = net.Alice.Client.CancelSwap(ht.ctx, &xudrpc.CancelSwapRequest{RHash: holdAck.RHash})
+	ht.assert.NoError(err)
+
+	// Wait for Alice to report the cancelled swap as failed rather than
+	// sleeping.
+	err = waitForSwapFailed(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	// Verify neither party's balance moved.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	ht.assert.Equal(alicePrevLtcBalance, aliceBalance.ltc.channel.GetBalance(), "alice's LTC balance should be unaffected by a cancelled hold swap")
+
+	bobBalance, err := getBalance(ht.ctx, net.Bob)
+	ht.assert.NoError(err)
+	ht.assert.Equal(bobPrevBtcBalance, bobBalance.btc.channel.GetBalance(), "bob's BTC HTLC should be returned cleanly after the hold swap is cancelled")
+}
+
+// testHoldSwapConnextCrashThenSettle has Alice hold an incoming ETH HTLC and
+// then explicitly settle it while her connext client is killed mid-attempt,
+// asserting that the held swap's preimage survives the crash and the swap
+// still completes once her connext client is back up. Unlike the
+// automatic-settlement crash tests above, the crash here is triggered by an
+// explicit SettleSwap call rather than xud's own swap processing.
+func testHoldSwapConnextCrashThenSettle(net *xudtest.NetworkHarness, ht *harnessTest) {
+	ht.act.init(net.Alice)
+	ht.act.initConnext(net, net.Alice, false)
+	ht.act.init(net.Bob)
+	ht.act.initConnext(net, net.Bob, true)
+
+	err := ht.act.injectFault(net.Alice, KillClient(xudrpc.ClientType_CONNEXT_CLIENT))
+	ht.assert.NoError(err)
+
+	// Connect Alice to Bob.
+	ht.act.connect(net.Alice, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, net.Bob)
+
+	err = openETHChannel(ht.ctx, net.Bob, 400, 0)
+	ht.assert.NoError(err)
+
+	// Save the initial balance.
+	alicePrevBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "ETH"})
+	ht.assert.NoError(err)
+	alicePrevEthBalance := alicePrevBalance.Balances["ETH"]
+
+	// Place a hold order on Alice; her incoming HTLC will be accepted but
+	// held rather than settled immediately, so the injected fault won't
+	// fire until SettleSwap is explicitly called below.
+	aliceOrderReq := &xudrpc.PlaceHoldOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    40,
+		Quantity: 1,
+		PairId:   "BTC/ETH",
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	// Subscribe before placing the matching order so the accepted event
+	// can't be missed.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	holdAck, err := net.Alice.Client.PlaceHoldOrder(ht.ctx, aliceOrderReq)
+	ht.assert.NoError(err)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Wait for the HTLC to be accepted before settling.
+	err = waitForSwapAccepted(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	// Explicitly settle the held swap; Alice's custom xud kills her connext
+	// client as soon as it's invoked to relay the settlement.
+	_, err = net.Alice.Client.SettleSwap(ht.ctx, &xudrpc.SettleSwapRequest{RHash: holdAck.RHash})
+	ht.assert.NoError(err)
+
+	<-net.Alice.ConnextClient.ProcessExit
+
+	err = net.Alice.ConnextClient.Start(nil)
+	ht.assert.NoError(err)
+
+	err = waitConnextReady(net.Alice)
+	ht.assert.NoError(err)
+
+	// Wait for the swap to be reported recovered once Alice's connext client
+	// retries the settlement, rather than sleeping.
+	err = waitForSwapRecovered(tracker, holdAck.RHash)
+	ht.assert.NoError(err)
+
+	// Verify that alice received her ETH despite the crash mid-settle.
+	aliceBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "ETH"})
+	ht.assert.NoError(err)
+	diff := uint64(float64(aliceOrderReq.Quantity) * aliceOrderReq.Price)
+	ht.assert.Equal(alicePrevEthBalance.ChannelBalance+diff, aliceBalance.Balances["ETH"].ChannelBalance, "alice did not recover ETH after settling the held swap across a connext crash")
+}
+
+// testThreeHopSwap builds a three-node topology (Alice - Carol - Bob),
+// analogous to lnd's createThreeHopNetwork, and routes an LTC/BTC swap
+// between Alice and Bob across Carol's channels rather than connecting
+// them directly. It also asserts that Carol's forwarding-fee accounting
+// reflects the hop.
+func testThreeHopSwap(net *xudtest.NetworkHarness, ht *harnessTest) {
+	carol, err := net.AddNode(ht.ctx, ht, "carol", nil)
+	ht.assert.NoError(err)
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+	ht.act.init(carol)
+
+	// Connect Alice - Carol - Bob; Alice and Bob are not directly
+	// connected, so the swap must route through Carol.
+	ht.act.connect(net.Alice, carol)
+	ht.act.connect(carol, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, carol)
+	ht.act.verifyConnectivity(carol, net.Bob)
+
+	// Save the initial balance and Carol's forwarding-fee accounting.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	carolPrevInfo, err := carol.Client.GetInfo(ht.ctx, &xudrpc.GetInfoRequest{})
+	ht.assert.NoError(err)
+	carolPrevForwardingFees := carolPrevInfo.ForwardingFeesCollected
+
+	// Subscribe before placing orders so the settle event can't be missed.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob; the order only reaches Bob via
+	// Carol's forwarded order broadcast.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	_, err = net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+	ht.assert.NoError(err)
+
+	// Wait for the swap to route through Carol and settle rather than
+	// sleeping for a fixed duration.
+	err = waitForSwapPaid(tracker, "")
+	ht.assert.NoError(err)
+
+	// Verify that alice received her LTC.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	aliceLtcBalance := aliceBalance.ltc.channel.GetBalance()
+	ht.assert.Equal(alicePrevLtcBalance+ltcQuantity, aliceLtcBalance, "alice did not receive LTC via the routed swap")
+
+	// Verify that Carol's forwarding-fee accounting reflects the hop.
+	carolInfo, err := carol.Client.GetInfo(ht.ctx, &xudrpc.GetInfoRequest{})
+	ht.assert.NoError(err)
+	ht.assert.Greater(carolInfo.ForwardingFeesCollected, carolPrevForwardingFees, "carol's forwarding-fee accounting did not reflect the routed swap")
+}
+
+// testThreeHopCarolCrashMidRoute has Carol crash after forwarding the
+// outgoing leg to Bob but before settling the incoming leg back to Alice,
+// and asserts that both endpoints recover their funds once Carol restarts.
+func testThreeHopCarolCrashMidRoute(net *xudtest.NetworkHarness, ht *harnessTest) {
+	carol, err := net.AddNode(ht.ctx, ht, "carol", nil)
+	ht.assert.NoError(err)
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+	ht.act.init(carol)
+
+	ht.act.connect(net.Alice, carol)
+	ht.act.connect(carol, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, carol)
+	ht.act.verifyConnectivity(carol, net.Bob)
+
+	err = ht.act.injectFault(carol, CrashAfter(xudrpc.FaultStage_STAGE_FORWARD_SETTLE))
+	ht.assert.NoError(err)
+
+	// Save the initial balances.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	bobPrevBalance, err := getBalance(ht.ctx, net.Bob)
+	ht.assert.NoError(err)
+	bobPrevBtcBalance := bobPrevBalance.btc.channel.GetBalance()
+
+	// Subscribe to both endpoints' swap events up front; their xuds stay up
+	// throughout this test, only Carol crashes.
+	aliceTracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+	bobTracker, err := trackSwaps(ht, net.Bob)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Carol crashes mid-route after forwarding the outgoing leg to Bob but
+	// before settling the incoming leg back to Alice.
+	<-carol.ProcessExit
+
+	err = carol.Start(nil)
+	ht.assert.NoError(err)
+
+	// Wait for both legs to be recovered rather than sleeping.
+	err = waitForSwapRecovered(aliceTracker, "")
+	ht.assert.NoError(err)
+	err = waitForSwapRecovered(bobTracker, "")
+	ht.assert.NoError(err)
+
+	// Verify that both endpoints completed the swap despite Carol's crash.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	ht.assert.Equal(alicePrevLtcBalance+ltcQuantity, aliceBalance.ltc.channel.GetBalance(), "alice did not recover her LTC across Carol's crash")
+
+	bobBalance, err := getBalance(ht.ctx, net.Bob)
+	ht.assert.NoError(err)
+	diff := uint64(float64(aliceOrderReq.Quantity) * aliceOrderReq.Price)
+	ht.assert.Equal(bobPrevBtcBalance+diff, bobBalance.btc.channel.GetBalance(), "bob did not recover his BTC across Carol's crash")
+}
+
+// testThreeHopCarolLndLtcDiesBeforeForward kills Carol's lnd-ltc before she
+// can forward Bob's settle message back to Alice, and asserts that Alice
+// still receives her LTC once Carol's lnd-ltc is back up.
+func testThreeHopCarolLndLtcDiesBeforeForward(net *xudtest.NetworkHarness, ht *harnessTest) {
+	carol, err := net.AddNode(ht.ctx, ht, "carol", nil)
+	ht.assert.NoError(err)
+	ht.act.init(net.Alice)
+	ht.act.init(net.Bob)
+	ht.act.init(carol)
+
+	ht.act.connect(net.Alice, carol)
+	ht.act.connect(carol, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, carol)
+	ht.act.verifyConnectivity(carol, net.Bob)
+
+	err = ht.act.injectFault(carol, KillClient(xudrpc.ClientType_LND_LTC))
+	ht.assert.NoError(err)
+
+	// Subscribe to Alice's swap events up front; her xud stays up
+	// throughout this test, only Carol's lnd-ltc dies.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Save the initial balance.
+	alicePrevBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	alicePrevLtcBalance := alicePrevBalance.ltc.channel.GetBalance()
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    0.02,
+		Quantity: uint64(ltcQuantity),
+		PairId:   "LTC/BTC",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Carol's lnd-ltc is expected to be killed before she can forward the
+	// settle message from Bob back to Alice.
+	<-carol.LndLtcNode.ProcessExit
+
+	err = carol.LndLtcNode.Start(nil)
+	ht.assert.NoError(err)
+
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
+
+	// Verify that alice received her LTC once Carol's lnd-ltc recovered.
+	aliceBalance, err := getBalance(ht.ctx, net.Alice)
+	ht.assert.NoError(err)
+	ht.assert.Equal(alicePrevLtcBalance+ltcQuantity, aliceBalance.ltc.channel.GetBalance(), "alice did not recover her LTC after Carol's lnd-ltc recovered")
+}
+
+// testThreeHopCarolConnextRestartMidRoute routes a BTC/ETH swap through
+// Carol and kills her connext client while the ETH leg to Bob is inflight,
+// asserting that Alice still recovers her BTC once Carol's connext client
+// restarts.
+func testThreeHopCarolConnextRestartMidRoute(net *xudtest.NetworkHarness, ht *harnessTest) {
+	carol, err := net.AddNode(ht.ctx, ht, "carol", nil)
+	ht.assert.NoError(err)
+	ht.act.init(net.Alice)
+	ht.act.initConnext(net, net.Alice, true)
+	ht.act.init(net.Bob)
+	ht.act.init(carol)
+	ht.act.initConnext(net, carol, false)
+
+	ht.act.connect(net.Alice, carol)
+	ht.act.connect(carol, net.Bob)
+	ht.act.verifyConnectivity(net.Alice, carol)
+	ht.act.verifyConnectivity(carol, net.Bob)
+
+	err = openETHChannel(ht.ctx, carol, 400, 0)
+	ht.assert.NoError(err)
+
+	// Save the initial balance.
+	alicePrevBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "BTC"})
+	ht.assert.NoError(err)
+	alicePrevBtcBalance := alicePrevBalance.Balances["BTC"]
+
+	err = ht.act.injectFault(carol, KillClient(xudrpc.ClientType_CONNEXT_CLIENT))
+	ht.assert.NoError(err)
+
+	// Subscribe to Alice's swap events up front; her xud stays up
+	// throughout this test, only Carol's connext client dies.
+	tracker, err := trackSwaps(ht, net.Alice)
+	ht.assert.NoError(err)
+
+	// Place an order on Alice.
+	aliceOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "maker_order_id",
+		Price:    40,
+		Quantity: 1,
+		PairId:   "BTC/ETH",
+		Side:     xudrpc.OrderSide_BUY,
+	}
+	ht.act.placeOrderAndBroadcast(net.Alice, net.Bob, aliceOrderReq)
+
+	// Place a matching order on Bob.
+	bobOrderReq := &xudrpc.PlaceOrderRequest{
+		OrderId:  "taker_order_id",
+		Price:    aliceOrderReq.Price,
+		Quantity: aliceOrderReq.Quantity,
+		PairId:   aliceOrderReq.PairId,
+		Side:     xudrpc.OrderSide_SELL,
+	}
+	go net.Bob.Client.PlaceOrderSync(ht.ctx, bobOrderReq)
+
+	// Carol's connext client is expected to be killed while the ETH leg to
+	// Bob is inflight.
+	<-carol.ConnextClient.ProcessExit
+
+	err = carol.ConnextClient.Start(nil)
+	ht.assert.NoError(err)
+
+	err = waitConnextReady(carol)
+	ht.assert.NoError(err)
+
+	err = waitForSwapRecovered(tracker, "")
+	ht.assert.NoError(err)
+
+	// Verify that alice recovered her BTC despite Carol's connext restart.
+	aliceBalance, err := net.Alice.Client.GetBalance(ht.ctx, &xudrpc.GetBalanceRequest{Currency: "BTC"})
+	ht.assert.NoError(err)
+	diff := aliceOrderReq.Quantity
+	ht.assert.Equal(alicePrevBtcBalance.ChannelBalance+diff, aliceBalance.Balances["BTC"].ChannelBalance, "alice did not recover her BTC across Carol's connext restart")
+}
@@ -0,0 +1,34 @@
+// Package wait contains helpers for polling test conditions instead of
+// sleeping for a fixed duration, which is slower than necessary in the
+// common case and flaky under CI load in the uncommon one.
+package wait
+
+import (
+	"fmt"
+	"time"
+)
+
+// pollInterval is how often Predicate rechecks its condition.
+const pollInterval = 200 * time.Millisecond
+
+// Predicate is a helper test function that will wait for a timeout period of
+// time until the passed predicate returns true. This function is useful as
+// timing doesn't always line up well when running integration tests with
+// several running processes. It returns an error if the predicate did not
+// become true within the timeout.
+func Predicate(pred func() bool, timeout time.Duration) error {
+	exitTimer := time.After(timeout)
+	for {
+		select {
+		case <-exitTimer:
+			return fmt.Errorf("predicate not satisfied after time out")
+		default:
+		}
+
+		if pred() {
+			return nil
+		}
+
+		time.Sleep(pollInterval)
+	}
+}